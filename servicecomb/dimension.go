@@ -0,0 +1,139 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package servicecomb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-mesh/openlogging"
+)
+
+const (
+	// DefaultDIConcurrency bounds how many dimensions PullConfigsByDI queries at once.
+	DefaultDIConcurrency = 8
+	// DefaultDITimeout bounds each dimension's PullConfigsWithLabels call.
+	DefaultDITimeout = 10 * time.Second
+)
+
+// dimension is one project[:label=value;label=value] entry parsed out of a
+// dimensionInfo string, following the config-center plugin's convention.
+type dimension struct {
+	raw     string
+	project string
+	labels  map[string]string
+}
+
+func parseDimensionInfo(dimensionInfo string) []dimension {
+	var dims []dimension
+	for _, entry := range strings.Split(dimensionInfo, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		d := dimension{raw: entry, project: entry, labels: map[string]string{}}
+		if idx := strings.Index(entry, ":"); idx >= 0 {
+			d.project = entry[:idx]
+			for _, kv := range strings.Split(entry[idx+1:], ";") {
+				kv = strings.TrimSpace(kv)
+				if kv == "" {
+					continue
+				}
+				parts := strings.SplitN(kv, "=", 2)
+				if len(parts) == 2 {
+					d.labels[parts[0]] = parts[1]
+				}
+			}
+		}
+		dims = append(dims, d)
+	}
+	return dims
+}
+
+// multiError aggregates the per-dimension errors of PullConfigsByDI so one
+// failing project doesn't hide the partial success of the others.
+type multiError struct {
+	total int
+	errs  []error
+}
+
+func (m *multiError) Error() string {
+	msgs := make([]string, 0, len(m.errs))
+	for _, e := range m.errs {
+		msgs = append(msgs, e.Error())
+	}
+	return fmt.Sprintf("%d of %d dimensions failed: %s", len(m.errs), m.total, strings.Join(msgs, "; "))
+}
+
+// PullConfigsByDI returns the configurations of every project/dimension in
+// dimensionInfo, a comma-separated list of `project[:label=value;label=value]`
+// entries. Dimensions are queried concurrently, bounded by DIConcurrency, and
+// each query is bounded by DITimeout.
+func (c *Client) PullConfigsByDI(dimensionInfo string) (map[string]map[string]interface{}, error) {
+	dims := parseDimensionInfo(dimensionInfo)
+	result := make(map[string]map[string]interface{}, len(dims))
+
+	concurrency := c.DIConcurrency
+	if concurrency <= 0 {
+		concurrency = DefaultDIConcurrency
+	}
+	timeout := c.DITimeout
+	if timeout <= 0 {
+		timeout = DefaultDITimeout
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	merr := &multiError{total: len(dims)}
+
+	for _, d := range dims {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(d dimension) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			labels := d.labels
+			if len(labels) == 0 {
+				labels = c.opts.Labels
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			configsInfo, err := c.PullConfigsWithLabels(ctx, labels, WithQueryProject(d.project))
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				openlogging.GetLogger().Errorf("PullConfigsByDI failed for dimension %q: %s", d.raw, err.Error())
+				merr.errs = append(merr.errs, fmt.Errorf("%s: %s", d.raw, err.Error()))
+				return
+			}
+			result[d.raw] = configsInfo
+		}(d)
+	}
+	wg.Wait()
+
+	if len(merr.errs) > 0 {
+		return result, merr
+	}
+	return result, nil
+}
@@ -0,0 +1,56 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package servicecomb
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseDimensionInfo(t *testing.T) {
+	dims := parseDimensionInfo("svcA:region=us;dc=dc1, svcB, svcC:canaryGroup=blue")
+	if len(dims) != 3 {
+		t.Fatalf("expected 3 dimensions, got %d: %#v", len(dims), dims)
+	}
+	if dims[0].project != "svcA" || !reflect.DeepEqual(dims[0].labels, map[string]string{"region": "us", "dc": "dc1"}) {
+		t.Fatalf("unexpected dimension 0: %#v", dims[0])
+	}
+	if dims[1].project != "svcB" || len(dims[1].labels) != 0 {
+		t.Fatalf("unexpected dimension 1: %#v", dims[1])
+	}
+	if dims[2].project != "svcC" || !reflect.DeepEqual(dims[2].labels, map[string]string{"canaryGroup": "blue"}) {
+		t.Fatalf("unexpected dimension 2: %#v", dims[2])
+	}
+}
+
+func TestParseDimensionInfoIgnoresBlankEntries(t *testing.T) {
+	dims := parseDimensionInfo(" , svcA, ,")
+	if len(dims) != 1 || dims[0].project != "svcA" {
+		t.Fatalf("expected a single svcA dimension, got %#v", dims)
+	}
+}
+
+func TestMultiErrorReportsFailureCount(t *testing.T) {
+	merr := &multiError{total: 3, errs: []error{errors.New("boom")}}
+	got := merr.Error()
+	want := "1 of 3 dimensions failed: boom"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package servicecomb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-mesh/openlogging"
+)
+
+// HistoryEntry is a single historical revision of a KV, as tracked by kie's
+// revision store.
+type HistoryEntry struct {
+	ID             string
+	Value          string
+	Labels         map[string]string
+	CreateRevision int64
+	UpdateRevision int64
+	UpdateTime     string
+}
+
+// HistoryClient is an optional capability a config.Client plugin can
+// implement on top of kie's revision store. go-chassis consumers that need
+// audit or rollback should type-assert their config.Client onto this
+// interface rather than depend on the concrete servicecomb.Client type, so
+// that plugins without history support keep working unchanged.
+type HistoryClient interface {
+	GetHistory(key string, labels map[string]string, limit int) ([]HistoryEntry, error)
+	Rollback(key string, labels map[string]string, revision string) error
+}
+
+// GetHistory returns the revision history of key scoped to labels, capped at
+// limit entries (0 means no cap). kie tracks history per kv_id, so key is
+// first resolved to its id via a List call.
+func (c *Client) GetHistory(key string, labels map[string]string, limit int) ([]HistoryEntry, error) {
+	project := c.projectFor(labels)
+	merged := c.mergeCustomLabels(labels)
+	kvID, err := c.resolveKVID(context.TODO(), project, key, merged)
+	if err != nil {
+		openlogging.GetLogger().Errorf("Error resolving kv_id for %s: %s", key, err.Error())
+		return nil, err
+	}
+	if kvID == "" {
+		return nil, fmt.Errorf("can not find value for key %s", key)
+	}
+	resp, err := c.transport.listHistory(context.TODO(), project, kvID, 0, limit)
+	if err != nil {
+		openlogging.GetLogger().Errorf("Error in ListHistory from Kie: %s", err.Error())
+		return nil, err
+	}
+	entries := make([]HistoryEntry, 0, len(resp.Data))
+	for _, h := range resp.Data {
+		entries = append(entries, HistoryEntry{
+			ID:             h.ID,
+			Value:          h.Value,
+			Labels:         h.Labels,
+			CreateRevision: h.CreateRevision,
+			UpdateRevision: h.UpdateRevision,
+			UpdateTime:     h.UpdateTime,
+		})
+	}
+	return entries, nil
+}
+
+// Rollback restores key/labels to the value it held at revision, by reading
+// the historical entry and issuing a Put with its value. revision may be
+// either a history entry's ID or its UpdateRevision.
+func (c *Client) Rollback(key string, labels map[string]string, revision string) error {
+	history, err := c.GetHistory(key, labels, 0)
+	if err != nil {
+		return err
+	}
+	for _, h := range history {
+		if h.ID == revision || strconv.FormatInt(h.UpdateRevision, 10) == revision {
+			_, err := c.PushConfigWithLabels(context.TODO(), key, h.Value, labels)
+			return err
+		}
+	}
+	return fmt.Errorf("revision %s not found for key %s", revision, key)
+}
@@ -0,0 +1,98 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package servicecomb
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+)
+
+func newHistoryTestServer(t *testing.T, kvID string, history []*model.DocResponseSingleKey) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/svc/kie/kv":
+			json.NewEncoder(w).Encode(model.DocResponseGetKey{Data: []*model.DocResponseSingleKey{{ID: kvID, Key: "k1", Value: "current"}}})
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/svc/kie/revision/"+kvID:
+			json.NewEncoder(w).Encode(model.DocResponseGetKey{Data: history})
+		case r.Method == http.MethodPut && r.URL.Path == "/v1/svc/kie/kv/"+kvID:
+			var body updateKVBody
+			json.NewDecoder(r.Body).Decode(&body)
+			json.NewEncoder(w).Encode(model.DocResponseSingleKey{ID: kvID, Key: "k1", Value: body.Value})
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+}
+
+func TestGetHistoryResolvesKVIDAndReturnsEntries(t *testing.T) {
+	server := newHistoryTestServer(t, "kv-1", []*model.DocResponseSingleKey{
+		{ID: "kv-1", Key: "k1", Value: "v1", CreateRevision: 1, UpdateRevision: 2, UpdateTime: "2026-07-01T00:00:00Z"},
+		{ID: "kv-1", Key: "k1", Value: "v0", CreateRevision: 1, UpdateRevision: 1, UpdateTime: "2026-06-01T00:00:00Z"},
+	})
+	defer server.Close()
+
+	c := &Client{transport: newTransport(server.URL, http.DefaultClient)}
+	entries, err := c.GetHistory("k1", map[string]string{"serviceName": "svc"}, 0)
+	if err != nil {
+		t.Fatalf("GetHistory: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Value != "v1" || entries[0].UpdateRevision != 2 {
+		t.Fatalf("unexpected entries: %#v", entries)
+	}
+}
+
+func TestGetHistoryErrorsWhenKeyNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(model.DocResponseGetKey{})
+	}))
+	defer server.Close()
+
+	c := &Client{transport: newTransport(server.URL, http.DefaultClient)}
+	if _, err := c.GetHistory("missing", map[string]string{"serviceName": "svc"}, 0); err == nil {
+		t.Fatal("expected an error when the key does not exist")
+	}
+}
+
+func TestRollbackRestoresHistoricalValue(t *testing.T) {
+	server := newHistoryTestServer(t, "kv-1", []*model.DocResponseSingleKey{
+		{ID: "kv-1", Key: "k1", Value: "v1", UpdateRevision: 2},
+		{ID: "kv-1", Key: "k1", Value: "v0", UpdateRevision: 1},
+	})
+	defer server.Close()
+
+	c := &Client{transport: newTransport(server.URL, http.DefaultClient)}
+	if err := c.Rollback("k1", map[string]string{"serviceName": "svc"}, "1"); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+}
+
+func TestRollbackErrorsWhenRevisionNotFound(t *testing.T) {
+	server := newHistoryTestServer(t, "kv-1", []*model.DocResponseSingleKey{
+		{ID: "kv-1", Key: "k1", Value: "v1", UpdateRevision: 2},
+	})
+	defer server.Close()
+
+	c := &Client{transport: newTransport(server.URL, http.DefaultClient)}
+	if err := c.Rollback("k1", map[string]string{"serviceName": "svc"}, "999"); err == nil {
+		t.Fatal("expected an error for an unknown revision")
+	}
+}
@@ -20,137 +20,194 @@ package servicecomb
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
 
-	"github.com/apache/servicecomb-kie/client"
-	"github.com/apache/servicecomb-kie/pkg/model"
 	"github.com/go-chassis/go-chassis-config"
 	"github.com/go-mesh/openlogging"
 )
 
-// Client contains the implementation of Client
+// Client implements config.Client against servicecomb-kie's REST API. There
+// is no Go client SDK for kie in go-chassis-config's dependency tree, so
+// every call goes through transport, a thin net/http wrapper.
 type Client struct {
-	KieClient     *client.Client
-	DefaultLabels map[string]string
-	opts          config.Options
+	transport *transport
+	opts      config.Options
+
+	// WatchWait, WatchBackoffBase and WatchBackoffCap tune the long-polling
+	// loop started by Watch. They default to the DefaultWatch* constants and
+	// may be overridden before calling Watch.
+	WatchWait        time.Duration
+	WatchBackoffBase time.Duration
+	WatchBackoffCap  time.Duration
+
+	watchCtx    context.Context
+	cancelWatch context.CancelFunc
+	search      search
+
+	// CustomLabels are merged into every pull/push on top of the caller's own
+	// labels, letting go-chassis users register e.g. canaryGroup=blue once
+	// instead of on every call.
+	CustomLabels map[string]string
+
+	// DIConcurrency and DITimeout bound PullConfigsByDI's per-dimension fan-out.
+	// They default to DefaultDIConcurrency/DefaultDITimeout and may be
+	// overridden before calling PullConfigsByDI.
+	DIConcurrency int
+	DITimeout     time.Duration
 }
 
-const (
-	//Name of the Plugin
-	Name             = "servicecomb-kie"
-	LabelService     = "serviceName"
-	LabelVersion     = "version"
-	LabelEnvironment = "environment"
-	LabelApp         = "app"
-)
+// Name of the Plugin
+const Name = "servicecomb-kie"
 
 // NewClient init the necessary objects needed for seamless communication to Kie Server
 func NewClient(options config.Options) (config.Client, error) {
-	kieClient := &Client{
-		opts: options,
+	if options.ServerURI == "" {
+		return nil, errors.New("ServerURI must not be empty")
 	}
-	DefaultLabels := map[string]string{
-		LabelApp:         options.App,
-		LabelEnvironment: options.Env,
-		LabelService:     options.ServiceName,
-		LabelVersion:     options.Version,
+	if options.EnableSSL && strings.HasPrefix(options.ServerURI, "http://") {
+		return nil, fmt.Errorf("EnableSSL is set but ServerURI %q is not an https endpoint", options.ServerURI)
 	}
-	configInfo := client.Config{Endpoint: kieClient.opts.ServerURI, DefaultLabels: DefaultLabels, VerifyPeer: kieClient.opts.EnableSSL}
+	// Callers that need a CA bundle, client cert/key or SNI name build it
+	// themselves via BuildTLSConfig and set it on options.TLSConfig. When
+	// EnableSSL is on but no TLSConfig was supplied, fall back to one backed
+	// by the system root pool instead of leaving VerifyPeer as the only signal.
+	tlsConf := options.TLSConfig
 	var err error
-	kieClient.KieClient, err = client.New(configInfo)
-	if err != nil {
-		openlogging.Error("KieClient Initialization Failed: " + err.Error())
+	if options.EnableSSL && tlsConf == nil {
+		tlsConf, err = BuildTLSConfig(TLSConfig{})
+		if err != nil {
+			return nil, fmt.Errorf("build default TLS config: %s", err.Error())
+		}
+	}
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConf}}
+
+	kieClient := &Client{
+		transport:        newTransport(options.ServerURI, httpClient),
+		opts:             options,
+		WatchWait:        DefaultWatchWait,
+		WatchBackoffBase: DefaultWatchBackoffBase,
+		WatchBackoffCap:  DefaultWatchBackoffCap,
+		DIConcurrency:    DefaultDIConcurrency,
+		DITimeout:        DefaultDITimeout,
+	}
+	kieClient.watchCtx, kieClient.cancelWatch = context.WithCancel(context.Background())
+	kieClient.search = searchKie(kieClient.transport)
+	kieClient.CustomLabels = make(map[string]string, len(options.Labels))
+	for k, v := range options.Labels {
+		kieClient.CustomLabels[k] = v
 	}
 	openlogging.Debug("KieClient Initialized successfully")
-	return kieClient, err
+	return kieClient, nil
 }
 
-// PullConfigs is used for pull config from servicecomb-kie
-func (c *Client) PullConfigs(serviceName, version, app, env string) (map[string]interface{}, error) {
+// projectFor picks the kie project a request is scoped to: the caller's own
+// serviceName label if it set one, otherwise the client's configured
+// serviceName, otherwise DefaultProject.
+func (c *Client) projectFor(labels map[string]string) string {
+	if project := labels[config.LabelService]; project != "" {
+		return project
+	}
+	if project := c.opts.Labels[config.LabelService]; project != "" {
+		return project
+	}
+	return DefaultProject
+}
+
+// PullConfigs pulls every KV matching each of labels, merging the results.
+// With no labels it falls back to the labels in the client's options; the
+// client's CustomLabels are merged into every label set either way.
+func (c *Client) PullConfigs(labels ...map[string]string) (map[string]interface{}, error) {
 	openlogging.Debug("KieClient begin PullConfigs")
-	labels := map[string]string{LabelService: serviceName, LabelVersion: version, LabelApp: app, LabelEnvironment: env}
-	labelsAppLevel := map[string]string{LabelApp: app, LabelEnvironment: env}
-	configsInfo := make(map[string]interface{})
-	configurationsValue, err := c.KieClient.SearchByLabels(context.TODO(), client.WithGetProject(serviceName), client.WithLabels(labels, labelsAppLevel))
-	if err != nil {
-		openlogging.GetLogger().Errorf("Error in Querying the Response from Kie %s %#v", err.Error(), labels)
-		return nil, err
+	if len(labels) == 0 {
+		labels = []map[string]string{c.opts.Labels}
 	}
-	openlogging.GetLogger().Debugf("KieClient SearchByLabels. %#v", labels)
-	//Parse config result.
-	for _, docRes := range configurationsValue {
-		for _, docInfo := range docRes.Data {
-			configsInfo[docInfo.Key] = docInfo.Value
+	result := make(map[string]interface{})
+	for _, labelSet := range labels {
+		configsInfo, err := c.PullConfigsWithLabels(context.TODO(), labelSet)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range configsInfo {
+			result[k] = v
 		}
 	}
-	return configsInfo, nil
+	return result, nil
 }
 
 // PullConfig get config by key and labels.
-func (c *Client) PullConfig(serviceName, version, app, env, key, contentType string) (interface{}, error) {
-	labels := map[string]string{LabelService: serviceName, LabelVersion: version, LabelApp: app, LabelEnvironment: env}
-	configurationsValue, err := c.KieClient.Get(context.TODO(), key, client.WithGetProject(serviceName), client.WithLabels(labels))
+func (c *Client) PullConfig(key, contentType string, labels map[string]string) (interface{}, error) {
+	project := c.projectFor(labels)
+	resp, _, _, err := c.transport.listKVs(context.TODO(), project, key, string(MatchExact), c.mergeCustomLabels(labels), 0, 0)
 	if err != nil {
 		openlogging.GetLogger().Error("Error in Querying the Response from Kie: " + err.Error())
 		return nil, err
 	}
-	for _, doc := range configurationsValue {
-		for _, kvDoc := range doc.Data {
-			if key == kvDoc.Key {
-				openlogging.GetLogger().Debugf("The Key Value of : ", kvDoc.Value)
-				return doc, nil
-			}
+	for _, kvDoc := range resp.Data {
+		if kvDoc.Key == key {
+			return kvDoc.Value, nil
 		}
 	}
 	return nil, errors.New("can not find value")
 }
 
-//PullConfigsByDI not implemented
-func (c *Client) PullConfigsByDI(dimensionInfo string) (map[string]map[string]interface{}, error) {
-	// TODO Return the configurations for customized Projects in Kie Configs
-	return nil, errors.New("not implemented")
-}
-
-//PushConfigs put config in kie by key and labels.
-func (c *Client) PushConfigs(data map[string]interface{}, serviceName, version, app, env string) (map[string]interface{}, error) {
-	var configReq model.KVDoc
-	labels := map[string]string{LabelService: serviceName, LabelVersion: version, LabelApp: app, LabelEnvironment: env}
+// PushConfigs puts every entry in data, tagged with labels.
+func (c *Client) PushConfigs(data map[string]interface{}, labels map[string]string) (map[string]interface{}, error) {
 	configResult := make(map[string]interface{})
 	for key, configValue := range data {
-		configReq.Key = key
-		configReq.Value = configValue.(string)
-		configReq.Labels = labels
-		configurationsValue, err := c.KieClient.Put(context.TODO(), configReq, client.WithProject(serviceName))
+		result, err := c.PushConfigWithLabels(context.TODO(), key, configValue, labels)
 		if err != nil {
-			openlogging.Error("Error in PushConfigs to Kie: " + err.Error())
 			return nil, err
 		}
-		openlogging.Debug("The Key Value of : " + configurationsValue.Value)
-		configResult[configurationsValue.Key] = configurationsValue.Value
+		for k, v := range result {
+			configResult[k] = v
+		}
 	}
 	return configResult, nil
 }
 
-//DeleteConfigsByKeys use keyId for delete
-func (c *Client) DeleteConfigsByKeys(keys []string, serviceName, version, app, env string) (map[string]interface{}, error) {
+// DeleteConfigsByKeys deletes each key, scoped to labels' project. kie only
+// deletes by kv_id, so each key is first resolved to its id via a List call.
+func (c *Client) DeleteConfigsByKeys(keys []string, labels map[string]string) (map[string]interface{}, error) {
 	result := make(map[string]interface{})
-	for _, keyId := range keys {
-		err := c.KieClient.Delete(context.TODO(), keyId, "", client.WithProject(serviceName))
+	project := c.projectFor(labels)
+	mergedLabels := c.mergeCustomLabels(labels)
+	for _, key := range keys {
+		kvID, err := c.resolveKVID(context.TODO(), project, key, mergedLabels)
 		if err != nil {
 			openlogging.Error("Error in Delete from Kie. " + err.Error())
 			return nil, err
 		}
-		openlogging.GetLogger().Debugf("Delete The KeyId:%s", keyId)
+		if kvID == "" {
+			continue
+		}
+		if err := c.transport.deleteKVs(context.TODO(), project, []string{kvID}); err != nil {
+			openlogging.Error("Error in Delete from Kie. " + err.Error())
+			return nil, err
+		}
+		openlogging.GetLogger().Debugf("Delete The Key:%s", key)
 	}
 	return result, nil
 }
 
-//Watch not implemented because kie not support.
-func (c *Client) Watch(f func(map[string]interface{}), errHandler func(err error)) error {
-	// TODO watch change events
-	return errors.New("not implemented")
+// resolveKVID looks up the kv_id of key within project/labels. It returns ""
+// with no error if the key doesn't exist, and an error for any other failure.
+func (c *Client) resolveKVID(ctx context.Context, project, key string, labels map[string]string) (string, error) {
+	resp, _, _, err := c.transport.listKVs(ctx, project, key, string(MatchExact), labels, 0, 0)
+	if err != nil {
+		return "", err
+	}
+	for _, kvDoc := range resp.Data {
+		if kvDoc.Key == key {
+			return kvDoc.ID, nil
+		}
+	}
+	return "", nil
 }
 
-//Options.
+// Options returns the options the client was constructed with.
 func (c *Client) Options() config.Options {
 	return c.opts
 }
@@ -0,0 +1,130 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package servicecomb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/apache/servicecomb-kie/pkg/common"
+	"github.com/go-mesh/openlogging"
+)
+
+// MatchMode controls how kie matches the labels of a query, mirroring the
+// server's `match` query parameter. The zero value requests kie's default
+// partial match; MatchExact is the only other pattern the server honors.
+type MatchMode string
+
+// MatchExact requires every label in the set to match exactly.
+const MatchExact MatchMode = MatchMode(common.PatternExact)
+
+// Option configures a label-scoped request to kie.
+type Option func(*queryOptions)
+
+type queryOptions struct {
+	project string
+	match   MatchMode
+}
+
+func newQueryOptions(defaultProject string, opts ...Option) *queryOptions {
+	qOpts := &queryOptions{project: defaultProject}
+	for _, opt := range opts {
+		opt(qOpts)
+	}
+	return qOpts
+}
+
+// WithQueryProject overrides the kie project the query is scoped to. Defaults
+// to the serviceName label of the query (see Client.projectFor).
+func WithQueryProject(project string) Option {
+	return func(o *queryOptions) { o.project = project }
+}
+
+// WithMatchMode sets kie's `match` query parameter.
+func WithMatchMode(mode MatchMode) Option {
+	return func(o *queryOptions) { o.match = mode }
+}
+
+// mergeCustomLabels layers the client's CustomLabels (e.g. canaryGroup=blue,
+// registered once by the go-chassis user) on top of the caller's own labels,
+// without letting them override labels the caller set explicitly.
+func (c *Client) mergeCustomLabels(labels map[string]string) map[string]string {
+	if len(c.CustomLabels) == 0 {
+		return labels
+	}
+	merged := make(map[string]string, len(labels)+len(c.CustomLabels))
+	for k, v := range c.CustomLabels {
+		merged[k] = v
+	}
+	for k, v := range labels {
+		merged[k] = v
+	}
+	return merged
+}
+
+// PullConfigsWithLabels pulls every KV matching a single label set, with opts
+// such as the project or match mode applied. PullConfigs calls it once per
+// label set.
+func (c *Client) PullConfigsWithLabels(ctx context.Context, labels map[string]string, opts ...Option) (map[string]interface{}, error) {
+	qOpts := newQueryOptions(c.projectFor(labels), opts...)
+	merged := c.mergeCustomLabels(labels)
+
+	resp, _, _, err := c.transport.listKVs(ctx, qOpts.project, "", string(qOpts.match), merged, 0, 0)
+	if err != nil {
+		openlogging.GetLogger().Errorf("Error in Querying the Response from Kie %s %#v", err.Error(), labels)
+		return nil, err
+	}
+	openlogging.GetLogger().Debugf("KieClient List. %#v", labels)
+	configsInfo := make(map[string]interface{}, len(resp.Data))
+	for _, kvDoc := range resp.Data {
+		configsInfo[kvDoc.Key] = kvDoc.Value
+	}
+	return configsInfo, nil
+}
+
+// PushConfigWithLabels writes a single KV tagged with an arbitrary label set,
+// creating it if it doesn't already exist under those labels and updating it
+// in place otherwise.
+func (c *Client) PushConfigWithLabels(ctx context.Context, key string, value interface{}, labels map[string]string) (map[string]interface{}, error) {
+	strValue, ok := value.(string)
+	if !ok {
+		return nil, errors.New("config value must be a string")
+	}
+	project := c.projectFor(labels)
+	merged := c.mergeCustomLabels(labels)
+
+	kvID, err := c.resolveKVID(ctx, project, key, merged)
+	if err != nil {
+		openlogging.Error("Error in PushConfigWithLabels to Kie: " + err.Error())
+		return nil, err
+	}
+	if kvID == "" {
+		doc, err := c.transport.createKV(ctx, project, key, merged, strValue)
+		if err != nil {
+			openlogging.Error("Error in PushConfigWithLabels to Kie: " + err.Error())
+			return nil, err
+		}
+		return map[string]interface{}{doc.Key: doc.Value}, nil
+	}
+	doc, err := c.transport.updateKV(ctx, project, kvID, strValue)
+	if err != nil {
+		openlogging.Error("Error in PushConfigWithLabels to Kie: " + err.Error())
+		return nil, err
+	}
+	return map[string]interface{}{doc.Key: doc.Value}, nil
+}
@@ -0,0 +1,148 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package servicecomb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+)
+
+func TestNewQueryOptionsDefaultsProjectAndAppliesOpts(t *testing.T) {
+	qOpts := newQueryOptions("svc", WithQueryProject("other"), WithMatchMode(MatchExact))
+	if qOpts.project != "other" {
+		t.Fatalf("WithQueryProject did not override project: %#v", qOpts)
+	}
+	if qOpts.match != MatchExact {
+		t.Fatalf("WithMatchMode did not set match: %#v", qOpts)
+	}
+
+	defaulted := newQueryOptions("svc")
+	if defaulted.project != "svc" || defaulted.match != "" {
+		t.Fatalf("expected default project and empty match, got %#v", defaulted)
+	}
+}
+
+func TestMergeCustomLabelsLeavesCallerLabelsWinning(t *testing.T) {
+	c := &Client{CustomLabels: map[string]string{"canaryGroup": "blue", "app": "fromCustom"}}
+
+	merged := c.mergeCustomLabels(map[string]string{"app": "fromCaller", "version": "1.0"})
+	want := map[string]string{"canaryGroup": "blue", "app": "fromCaller", "version": "1.0"}
+	if !reflect.DeepEqual(merged, want) {
+		t.Fatalf("mergeCustomLabels() = %#v, want %#v", merged, want)
+	}
+}
+
+func TestMergeCustomLabelsNoOpWhenEmpty(t *testing.T) {
+	c := &Client{}
+	labels := map[string]string{"app": "a"}
+	if got := c.mergeCustomLabels(labels); !reflect.DeepEqual(got, labels) {
+		t.Fatalf("mergeCustomLabels() = %#v, want %#v unchanged", got, labels)
+	}
+}
+
+func newLabelsTestClient(serverURL string) *Client {
+	return &Client{
+		transport: newTransport(serverURL, http.DefaultClient),
+	}
+}
+
+func TestPullConfigsWithLabelsQueriesListEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/svc/kie/kv" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.URL.Query().Get("label"); got != "app:mall" {
+			t.Fatalf("unexpected label query param: %q", got)
+		}
+		resp := model.DocResponseGetKey{Data: []*model.DocResponseSingleKey{{Key: "k1", Value: "v1"}}}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	c := newLabelsTestClient(server.URL)
+	got, err := c.PullConfigsWithLabels(context.Background(), map[string]string{"app": "mall"}, WithQueryProject("svc"))
+	if err != nil {
+		t.Fatalf("PullConfigsWithLabels: %v", err)
+	}
+	if got["k1"] != "v1" {
+		t.Fatalf("unexpected result: %#v", got)
+	}
+}
+
+func TestPushConfigWithLabelsCreatesWhenKeyMissing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/svc/kie/kv":
+			json.NewEncoder(w).Encode(model.DocResponseGetKey{})
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/svc/kie/kv":
+			var body createKVBody
+			json.NewDecoder(r.Body).Decode(&body)
+			json.NewEncoder(w).Encode(model.DocResponseSingleKey{Key: body.Key, Value: body.Value})
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	c := newLabelsTestClient(server.URL)
+	got, err := c.PushConfigWithLabels(context.Background(), "k1", "v1", map[string]string{"serviceName": "svc"})
+	if err != nil {
+		t.Fatalf("PushConfigWithLabels: %v", err)
+	}
+	if got["k1"] != "v1" {
+		t.Fatalf("unexpected result: %#v", got)
+	}
+}
+
+func TestPushConfigWithLabelsUpdatesWhenKeyExists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/svc/kie/kv":
+			json.NewEncoder(w).Encode(model.DocResponseGetKey{Data: []*model.DocResponseSingleKey{{ID: "existing-id", Key: "k1", Value: "old"}}})
+		case r.Method == http.MethodPut && r.URL.Path == "/v1/svc/kie/kv/existing-id":
+			var body updateKVBody
+			json.NewDecoder(r.Body).Decode(&body)
+			json.NewEncoder(w).Encode(model.DocResponseSingleKey{Key: "k1", Value: body.Value})
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	c := newLabelsTestClient(server.URL)
+	got, err := c.PushConfigWithLabels(context.Background(), "k1", "new", map[string]string{"serviceName": "svc"})
+	if err != nil {
+		t.Fatalf("PushConfigWithLabels: %v", err)
+	}
+	if got["k1"] != "new" {
+		t.Fatalf("unexpected result: %#v", got)
+	}
+}
+
+func TestPushConfigWithLabelsRejectsNonStringValue(t *testing.T) {
+	c := &Client{}
+	if _, err := c.PushConfigWithLabels(context.Background(), "k1", 42, nil); err == nil {
+		t.Fatal("expected an error for a non-string value")
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package servicecomb
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// TLSConfig describes the material needed to build a *tls.Config for talking
+// to a kie server over TLS/mTLS: a CA bundle to verify the server (falling
+// back to the system roots when empty), and an optional client cert/key that
+// is presented to the kie server for mTLS.
+type TLSConfig struct {
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	ServerName         string
+	InsecureSkipVerify bool
+	MinVersion         uint16
+}
+
+// BuildTLSConfig loads the PEM files described by cfg into a *tls.Config. A
+// CA bundle, client cert/key, or SNI name beyond the defaults must go through
+// this function: go-chassis users call it once and store the result on
+// config.Options.TLSConfig before the kie plugin is constructed. NewClient
+// calls it with a zero-value TLSConfig (system roots, no client cert) when
+// EnableSSL is on but the caller didn't supply a TLSConfig of their own.
+func BuildTLSConfig(cfg TLSConfig) (*tls.Config, error) {
+	tlsConf := &tls.Config{
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		MinVersion:         cfg.MinVersion,
+	}
+
+	if cfg.CAFile == "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		tlsConf.RootCAs = pool
+	} else {
+		caPEM, err := ioutil.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA file: %s", err.Error())
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("no certificates found in CA file")
+		}
+		tlsConf.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" && cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client cert/key: %s", err.Error())
+		}
+		tlsConf.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConf, nil
+}
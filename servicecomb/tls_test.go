@@ -0,0 +1,78 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package servicecomb
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildTLSConfigTrustsSuppliedCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatalf("write CA file: %v", err)
+	}
+
+	tlsConf, err := BuildTLSConfig(TLSConfig{CAFile: caFile})
+	if err != nil {
+		t.Fatalf("BuildTLSConfig: %v", err)
+	}
+	if tlsConf.RootCAs == nil {
+		t.Fatal("expected RootCAs to be populated from CAFile")
+	}
+
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConf}}
+	resp, err := httpClient.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request using the supplied CA failed: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestBuildTLSConfigFallsBackToSystemRoots(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tlsConf, err := BuildTLSConfig(TLSConfig{})
+	if err != nil {
+		t.Fatalf("BuildTLSConfig: %v", err)
+	}
+
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConf}}
+	if _, err := httpClient.Get(server.URL); err == nil {
+		t.Fatal("expected the self-signed server cert to be rejected by the system root pool")
+	}
+}
+
+func TestBuildTLSConfigRejectsMissingCAFile(t *testing.T) {
+	if _, err := BuildTLSConfig(TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Fatal("expected an error for a missing CA file")
+	}
+}
@@ -0,0 +1,232 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package servicecomb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/servicecomb-kie/pkg/common"
+	"github.com/apache/servicecomb-kie/pkg/model"
+)
+
+// DefaultProject is the kie project used when neither an explicit project nor
+// a serviceName label is available.
+const DefaultProject = "default"
+
+// transport issues the raw HTTP calls servicecomb-kie's REST API expects.
+// go-chassis-config's dependency tree has no Go client SDK for kie, so the
+// plugin talks to /v1/{project}/kie/... directly instead of wrapping one.
+type transport struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+func newTransport(baseURL string, httpClient *http.Client) *transport {
+	return &transport{httpClient: httpClient, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+type createKVBody struct {
+	Key    string            `json:"key"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  string            `json:"value"`
+}
+
+type updateKVBody struct {
+	Value string `json:"value"`
+}
+
+type deleteKVsBody struct {
+	IDs []string `json:"ids"`
+}
+
+func (t *transport) request(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Response, error) {
+	u := t.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var reader *bytes.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("encode request body: %s", err.Error())
+		}
+		reader = bytes.NewReader(b)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return nil, fmt.Errorf("build %s %s request: %s", method, path, err.Error())
+	}
+	if body != nil {
+		req.Header.Set(common.HeaderContentType, common.ContentTypeJSON)
+	}
+	req.Header.Set(common.HeaderAccept, common.ContentTypeJSON)
+	return t.httpClient.Do(req)
+}
+
+// statusErr turns a non-2xx response into an error, draining the body into
+// the message so callers get the server's explanation.
+func statusErr(resp *http.Response) error {
+	body, _ := ioutil.ReadAll(resp.Body)
+	return fmt.Errorf("kie server returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
+
+func parseRevisionHeader(resp *http.Response) int64 {
+	revision, _ := strconv.ParseInt(resp.Header.Get(common.HeaderRevision), 10, 64)
+	return revision
+}
+
+func labelQueryValues(labels map[string]string) []string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+":"+v)
+	}
+	return pairs
+}
+
+// listKVs calls GET /v1/{project}/kie/kv. revision/wait are optional long-poll
+// parameters; zero values omit them. A 304 response is reported via notModified
+// with no error, matching the server's long-poll contract.
+func (t *transport) listKVs(ctx context.Context, project, key, match string, labels map[string]string, revision int64, wait time.Duration) (data *model.DocResponseGetKey, newRevision int64, notModified bool, err error) {
+	q := url.Values{}
+	if key != "" {
+		q.Set(common.QueryParamKey, key)
+	}
+	for _, pair := range labelQueryValues(labels) {
+		q.Add(common.QueryParamLabel, pair)
+	}
+	if match != "" {
+		q.Set(common.QueryParamMatch, match)
+	}
+	if revision > 0 {
+		q.Set(common.QueryParamRev, strconv.FormatInt(revision, 10))
+	}
+	if wait > 0 {
+		q.Set(common.QueryParamWait, wait.String())
+	}
+	resp, err := t.request(ctx, http.MethodGet, "/v1/"+project+"/kie/kv", q, nil)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	defer resp.Body.Close()
+	newRevision = parseRevisionHeader(resp)
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, newRevision, true, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newRevision, false, statusErr(resp)
+	}
+	var out model.DocResponseGetKey
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, newRevision, false, fmt.Errorf("decode kv list response: %s", err.Error())
+	}
+	return &out, newRevision, false, nil
+}
+
+// createKV calls POST /v1/{project}/kie/kv.
+func (t *transport) createKV(ctx context.Context, project, key string, labels map[string]string, value string) (*model.DocResponseSingleKey, error) {
+	resp, err := t.request(ctx, http.MethodPost, "/v1/"+project+"/kie/kv", nil, createKVBody{Key: key, Labels: labels, Value: value})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusErr(resp)
+	}
+	var out model.DocResponseSingleKey
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("decode create kv response: %s", err.Error())
+	}
+	return &out, nil
+}
+
+// updateKV calls PUT /v1/{project}/kie/kv/{kv_id}.
+func (t *transport) updateKV(ctx context.Context, project, kvID, value string) (*model.DocResponseSingleKey, error) {
+	resp, err := t.request(ctx, http.MethodPut, "/v1/"+project+"/kie/kv/"+kvID, nil, updateKVBody{Value: value})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusErr(resp)
+	}
+	var out model.DocResponseSingleKey
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("decode update kv response: %s", err.Error())
+	}
+	return &out, nil
+}
+
+// deleteKVs calls DELETE /v1/{project}/kie/kv/{kv_id} for a single id, or
+// DELETE /v1/{project}/kie/kv with a body for several.
+func (t *transport) deleteKVs(ctx context.Context, project string, ids []string) error {
+	if len(ids) == 1 {
+		resp, err := t.request(ctx, http.MethodDelete, "/v1/"+project+"/kie/kv/"+ids[0], nil, nil)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent {
+			return statusErr(resp)
+		}
+		return nil
+	}
+	resp, err := t.request(ctx, http.MethodDelete, "/v1/"+project+"/kie/kv", nil, deleteKVsBody{IDs: ids})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return statusErr(resp)
+	}
+	return nil
+}
+
+// listHistory calls GET /v1/{project}/kie/revision/{kv_id}.
+func (t *transport) listHistory(ctx context.Context, project, kvID string, offset, limit int) (*model.DocResponseGetKey, error) {
+	q := url.Values{}
+	if offset > 0 {
+		q.Set(common.QueryParamOffset, strconv.Itoa(offset))
+	}
+	if limit > 0 {
+		q.Set(common.QueryParamLimit, strconv.Itoa(limit))
+	}
+	resp, err := t.request(ctx, http.MethodGet, "/v1/"+project+"/kie/revision/"+kvID, q, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusErr(resp)
+	}
+	var out model.DocResponseGetKey
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("decode history response: %s", err.Error())
+	}
+	return &out, nil
+}
@@ -0,0 +1,116 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package servicecomb
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-mesh/openlogging"
+)
+
+const (
+	// DefaultWatchWait is how long the kie server is allowed to hold a watch request open
+	DefaultWatchWait = 30 * time.Second
+	// DefaultWatchBackoffBase is the initial delay after a failed long-poll
+	DefaultWatchBackoffBase = time.Second
+	// DefaultWatchBackoffCap is the maximum delay between reconnect attempts
+	DefaultWatchBackoffCap = 30 * time.Second
+)
+
+// search is the subset of the kie List call the watch loop depends on: it
+// returns the changed KVs (nil when the server answered 304 Not Modified) and
+// the revision to poll from next, taken off the X-Kie-Revision header.
+// NewClient points it at the real kie server; tests substitute a fake one so
+// the long-poll/backoff logic can be exercised without a network.
+type search func(ctx context.Context, project string, labels map[string]string, revision int64, wait time.Duration) (data map[string]interface{}, newRevision int64, err error)
+
+func searchKie(t *transport) search {
+	return func(ctx context.Context, project string, labels map[string]string, revision int64, wait time.Duration) (map[string]interface{}, int64, error) {
+		resp, newRevision, notModified, err := t.listKVs(ctx, project, "", string(MatchExact), labels, revision, wait)
+		if err != nil {
+			return nil, newRevision, err
+		}
+		if notModified {
+			return nil, newRevision, nil
+		}
+		data := make(map[string]interface{}, len(resp.Data))
+		for _, kvDoc := range resp.Data {
+			data[kvDoc.Key] = kvDoc.Value
+		}
+		return data, newRevision, nil
+	}
+}
+
+// Watch starts a long-polling loop against servicecomb-kie and invokes f whenever
+// the watched labels' KVs change. It returns once the initial snapshot has been
+// fetched; subsequent updates are delivered asynchronously until Close is called.
+func (c *Client) Watch(f func(map[string]interface{}), errHandler func(err error), labels map[string]string) error {
+	project := c.projectFor(labels)
+	mergedLabels := c.mergeCustomLabels(labels)
+	_, revision, err := c.search(context.TODO(), project, mergedLabels, 0, 0)
+	if err != nil {
+		openlogging.GetLogger().Errorf("Watch initial List failed: %s", err.Error())
+		return err
+	}
+	go c.watchLoop(project, mergedLabels, revision, f, errHandler)
+	return nil
+}
+
+// Close stops any in-flight Watch loop. It is safe to call multiple times.
+func (c *Client) Close() error {
+	if c.cancelWatch != nil {
+		c.cancelWatch()
+	}
+	return nil
+}
+
+func (c *Client) watchLoop(project string, labels map[string]string, revision int64, f func(map[string]interface{}), errHandler func(err error)) {
+	backoff := c.WatchBackoffBase
+	for {
+		select {
+		case <-c.watchCtx.Done():
+			return
+		default:
+		}
+		data, newRevision, err := c.search(c.watchCtx, project, labels, revision, c.WatchWait)
+		if err != nil {
+			if errHandler != nil {
+				errHandler(err)
+			}
+			select {
+			case <-c.watchCtx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > c.WatchBackoffCap {
+				backoff = c.WatchBackoffCap
+			}
+			continue
+		}
+		backoff = c.WatchBackoffBase
+		if newRevision > revision {
+			revision = newRevision
+		}
+		if len(data) == 0 {
+			continue
+		}
+		f(data)
+	}
+}
@@ -0,0 +1,135 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package servicecomb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chassis/go-chassis-config"
+)
+
+// fakeKieServer stands in for servicecomb-kie's long-poll endpoint: search
+// blocks until either a queued delta is available or wait elapses, just like
+// the real server holding a request open.
+type fakeKieServer struct {
+	mu       sync.Mutex
+	deltas   chan fakeDelta
+	failNext bool
+}
+
+type fakeDelta struct {
+	data     map[string]interface{}
+	revision int64
+}
+
+func newFakeKieServer() *fakeKieServer {
+	return &fakeKieServer{deltas: make(chan fakeDelta, 4)}
+}
+
+func (f *fakeKieServer) push(revision int64, data map[string]interface{}) {
+	f.deltas <- fakeDelta{data: data, revision: revision}
+}
+
+func (f *fakeKieServer) search(ctx context.Context, project string, labels map[string]string, revision int64, wait time.Duration) (map[string]interface{}, int64, error) {
+	f.mu.Lock()
+	fail := f.failNext
+	f.failNext = false
+	f.mu.Unlock()
+	if fail {
+		return nil, revision, errors.New("fake transport error")
+	}
+	select {
+	case delta := <-f.deltas:
+		return delta.data, delta.revision, nil
+	case <-ctx.Done():
+		return nil, revision, ctx.Err()
+	case <-time.After(wait):
+		return nil, revision, nil
+	}
+}
+
+func newWatchTestClient(fake *fakeKieServer) *Client {
+	c := &Client{
+		opts:             config.Options{Labels: map[string]string{config.LabelService: "svc", config.LabelVersion: "1.0"}},
+		WatchWait:        20 * time.Millisecond,
+		WatchBackoffBase: time.Millisecond,
+		WatchBackoffCap:  5 * time.Millisecond,
+	}
+	c.watchCtx, c.cancelWatch = context.WithCancel(context.Background())
+	c.search = fake.search
+	return c
+}
+
+func TestWatchDeliversDeltasAndStopsOnClose(t *testing.T) {
+	fake := newFakeKieServer()
+	c := newWatchTestClient(fake)
+
+	got := make(chan map[string]interface{}, 1)
+	if err := c.Watch(func(m map[string]interface{}) { got <- m }, func(error) {}, nil); err != nil {
+		t.Fatalf("Watch returned error: %v", err)
+	}
+
+	fake.push(1, map[string]interface{}{"k1": "v1"})
+
+	select {
+	case m := <-got:
+		if m["k1"] != "v1" {
+			t.Fatalf("unexpected delta: %#v", m)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for watch callback")
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+}
+
+func TestWatchReportsTransportErrorToErrHandler(t *testing.T) {
+	fake := newFakeKieServer()
+	c := newWatchTestClient(fake)
+
+	errs := make(chan error, 1)
+	if err := c.Watch(func(map[string]interface{}) {}, func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}, nil); err != nil {
+		t.Fatalf("Watch returned error: %v", err)
+	}
+
+	fake.mu.Lock()
+	fake.failNext = true
+	fake.mu.Unlock()
+
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Fatal("expected a transport error to reach errHandler")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for errHandler")
+	}
+
+	c.Close()
+}